@@ -0,0 +1,194 @@
+package goose
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrChecksumMismatch is returned by Verify when one or more applied
+// migrations no longer match the checksum recorded at apply time.
+var ErrChecksumMismatch = errors.New("goose: migration checksum mismatch")
+
+var whitespaceRun = regexp.MustCompile(`\s+`)
+
+// checksumMigration computes a stable SHA-256 hex digest for a migration.
+// SQL migrations are normalized (runs of whitespace collapsed) first, so
+// reformatting a file doesn't look like tampering, then hashed from disk
+// (or fsys, for embedded migrations). Go migrations registered via
+// AddMigration have no readable source once compiled into a binary -
+// m.Source is the build-time path captured by runtime.Caller, which
+// doesn't exist on a deployed host - so they're instead hashed from their
+// version and registered source name, which is enough to catch reordering
+// or renaming but not a change to the migration's Go code itself.
+func checksumMigration(fsys fs.FS, m *Migration) (string, error) {
+	if m.Registered {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", m.Version, m.Source)))
+		return hex.EncodeToString(sum[:]), nil
+	}
+
+	var b []byte
+	var err error
+
+	if fsys != nil {
+		b, err = fs.ReadFile(fsys, m.Source)
+	} else {
+		b, err = os.ReadFile(m.Source)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %q to compute checksum: %w", m.Source, err)
+	}
+
+	sum := sha256.Sum256([]byte(normalizeSQL(string(b))))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func normalizeSQL(s string) string {
+	return strings.TrimSpace(whitespaceRun.ReplaceAllString(s, " "))
+}
+
+// checksumColumnEnsured tracks which (db, tableName) pairs have already had
+// the ALTER TABLE below run against them. It's keyed on the *sql.DB pointer
+// rather than a DSN/name, since that's all goose has at hand, and on
+// tableName, since a single process may drive more than one Provider
+// against the same db with different table names (or the same table name
+// across different dbs) - a single global flag would skip the ALTER for
+// every pair but the first one it ran against.
+var checksumColumnEnsured sync.Map // map[checksumColumnKey]*sync.Once
+
+type checksumColumnKey struct {
+	db        *sql.DB
+	tableName string
+}
+
+// ensureChecksumColumn lazily adds the checksum column to an existing
+// goose_db_version table created before this column existed. The ALTER
+// only runs once per (db, tableName) - a migration run applies many
+// migrations in a loop, and re-issuing it for every one would mean an extra
+// DDL statement (and, on Postgres, an ACCESS EXCLUSIVE lock) per migration.
+func ensureChecksumColumn(db *sql.DB, tableName string) error {
+	key := checksumColumnKey{db: db, tableName: tableName}
+	once, _ := checksumColumnEnsured.LoadOrStore(key, &sync.Once{})
+
+	var err error
+	once.(*sync.Once).Do(func() {
+		_, execErr := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN checksum TEXT", tableName))
+		if execErr != nil && !isDuplicateColumnErr(execErr) {
+			err = execErr
+		}
+	})
+	return err
+}
+
+func isDuplicateColumnErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate column") || strings.Contains(msg, "already exists")
+}
+
+// getStoredChecksum returns the checksum recorded the last time version was
+// applied, and whether it has ever been applied at all. A version that was
+// applied and later rolled back still reports its last applied checksum.
+func getStoredChecksum(db *sql.DB, version int64, d SqlDialect, tableName string) (checksum string, applied bool, err error) {
+	row := db.QueryRow(d.checksumSQL(tableName), version)
+
+	var sum sql.NullString
+	if err := row.Scan(&sum); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	return sum.String, true, nil
+}
+
+// ChecksumMismatch describes an applied migration whose on-disk contents no
+// longer match the checksum recorded in the DB when it was applied.
+type ChecksumMismatch struct {
+	Version  int64
+	Source   string
+	Expected string
+	Actual   string
+}
+
+// VerifyChecksums re-hashes every migration in dir and compares it against
+// the checksum recorded in the DB, returning every mismatch found.
+// Migrations that were never applied, or applied before the checksum
+// column existed, are skipped rather than reported as drift.
+func VerifyChecksums(db *sql.DB, dir string) ([]ChecksumMismatch, error) {
+	return verifyChecksumsFS(nil, db, dir, dialect, TableName())
+}
+
+func verifyChecksumsFS(fsys fs.FS, db *sql.DB, dir string, d SqlDialect, tableName string) ([]ChecksumMismatch, error) {
+	var migrations Migrations
+	var err error
+	if fsys != nil {
+		migrations, err = collectMigrationsFS(fsys, dir)
+	} else {
+		migrations, err = collectMigrations(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureChecksumColumn(db, tableName); err != nil {
+		return nil, err
+	}
+
+	var mismatches []ChecksumMismatch
+	for _, m := range migrations {
+		stored, applied, err := getStoredChecksum(db, m.Version, d, tableName)
+		if err != nil {
+			return nil, err
+		}
+		if !applied || stored == "" {
+			continue
+		}
+
+		actual, err := checksumMigration(fsys, m)
+		if err != nil {
+			return nil, err
+		}
+
+		if actual != stored {
+			mismatches = append(mismatches, ChecksumMismatch{
+				Version:  m.Version,
+				Source:   m.Source,
+				Expected: stored,
+				Actual:   actual,
+			})
+		}
+	}
+
+	return mismatches, nil
+}
+
+// Verify re-hashes every on-disk migration in dir and compares it against
+// the checksum recorded in the DB, printing any drift it finds. It returns
+// ErrChecksumMismatch if any migration has drifted, which the CLI maps to
+// exit code 2.
+func Verify(db *sql.DB, dir string) error {
+	mismatches, err := VerifyChecksums(db, dir)
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		fmt.Println("goose: OK, no checksum drift detected")
+		return nil
+	}
+
+	for _, mm := range mismatches {
+		fmt.Printf("goose: DRIFT version %d (%s): recorded checksum %s, on-disk checksum %s\n",
+			mm.Version, mm.Source, mm.Expected, mm.Actual)
+	}
+
+	return ErrChecksumMismatch
+}
@@ -0,0 +1,130 @@
+package goose
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriver is a minimal database/sql/driver used only to exercise
+// ensureChecksumColumn's per-(db, tableName) scoping; this tree doesn't
+// vendor a real driver to test against.
+type fakeDriver struct {
+	mu     sync.Mutex
+	states map[string]*fakeState
+}
+
+type fakeState struct {
+	mu         sync.Mutex
+	alterCalls int
+}
+
+var fakeDriverInstance = &fakeDriver{states: map[string]*fakeState{}}
+
+func init() {
+	sql.Register("goosefake", fakeDriverInstance)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.states[name]
+	if !ok {
+		st = &fakeState{}
+		d.states[name] = st
+	}
+	return &fakeConn{state: st}, nil
+}
+
+type fakeConn struct {
+	state *fakeState
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{conn: c, query: query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "ALTER TABLE") && strings.Contains(s.query, "ADD COLUMN checksum") {
+		s.conn.state.mu.Lock()
+		s.conn.state.alterCalls++
+		s.conn.state.mu.Unlock()
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("goosefake: queries not supported")
+}
+
+func alterCallsFor(t *testing.T, dbName string) int {
+	t.Helper()
+	fakeDriverInstance.mu.Lock()
+	defer fakeDriverInstance.mu.Unlock()
+	st, ok := fakeDriverInstance.states[dbName]
+	if !ok {
+		return 0
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.alterCalls
+}
+
+func TestEnsureChecksumColumnScopedPerDBAndTable(t *testing.T) {
+	db1, err := sql.Open("goosefake", t.Name()+"/db1")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db1.Close()
+
+	if err := ensureChecksumColumn(db1, "goose_db_version"); err != nil {
+		t.Fatalf("ensureChecksumColumn: %v", err)
+	}
+	if err := ensureChecksumColumn(db1, "goose_db_version"); err != nil {
+		t.Fatalf("ensureChecksumColumn (second call): %v", err)
+	}
+	if got := alterCallsFor(t, t.Name()+"/db1"); got != 1 {
+		t.Fatalf("ALTER ran %d times for db1/goose_db_version, want 1", got)
+	}
+
+	// A second table name against the same db must still get its own ALTER -
+	// it's a different tenant's table and hasn't had the column added yet.
+	if err := ensureChecksumColumn(db1, "tenant_b_version"); err != nil {
+		t.Fatalf("ensureChecksumColumn (second table): %v", err)
+	}
+	if got := alterCallsFor(t, t.Name()+"/db1"); got != 2 {
+		t.Fatalf("ALTER ran %d times total for db1, want 2 (one per table name)", got)
+	}
+
+	// A second db with the same table name must also get its own ALTER - the
+	// guard is keyed on the *sql.DB too, not just the table name.
+	db2, err := sql.Open("goosefake", t.Name()+"/db2")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db2.Close()
+
+	if err := ensureChecksumColumn(db2, "goose_db_version"); err != nil {
+		t.Fatalf("ensureChecksumColumn (second db): %v", err)
+	}
+	if got := alterCallsFor(t, t.Name()+"/db2"); got != 1 {
+		t.Fatalf("ALTER ran %d times for db2/goose_db_version, want 1", got)
+	}
+}
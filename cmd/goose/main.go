@@ -4,10 +4,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/pressly/goose"
 
@@ -22,11 +26,22 @@ var (
 	flags = flag.NewFlagSet("goose", flag.ExitOnError)
 	dir   = flags.String("dir", ".", "directory with migration files")
 
-	tlsName       = flags.String("tlsname", "", "name of the TLS cert")
-	caCert        = flags.String("cacert", "", "CA Cert file")
-	clientCert    = flags.String("clientcert", "", "Client Cert file")
-	clientKey     = flags.String("clientkey", "", "Client Key file")
-	useClientCert = flags.Bool("useclientcert", false, "Use client cert to connect")
+	tlsName          = flags.String("tlsname", "", "name of the TLS cert (mysql only)")
+	caCert           = flags.String("cacert", "", "CA Cert file")
+	clientCert       = flags.String("clientcert", "", "Client Cert file")
+	clientKey        = flags.String("clientkey", "", "Client Key file")
+	useClientCert    = flags.Bool("useclientcert", false, "Use client cert to connect")
+	sslMode          = flags.String("sslmode", "", "postgres/redshift sslmode: disable|require|verify-ca|verify-full")
+	sslAllowInsecure = flags.Bool("sslallowinsecure", false, "allow encrypted transport without verifying the server cert (mysql: InsecureSkipVerify, postgres: sslmode=require)")
+
+	lockTimeout = flags.Duration("locktimeout", 0, "how long to wait to acquire the migration lock before giving up (0 = wait indefinitely)")
+	noLock      = flags.Bool("nolock", false, "skip the distributed advisory lock, e.g. when only a single process ever migrates this DB")
+
+	logFormat = flags.String("log-format", "text", "log output format: text|json (json emits one event per migration)")
+	dryRun    = flags.Bool("dry-run", false, "print the migrations up/up-to/down/down-to would run, without applying them (reading the current version still creates the version table if it is missing)")
+	format    = flags.String("format", "text", "status output format: text|json")
+
+	strict = flags.Bool("strict", false, "refuse to run up/up-to if any already-applied migration's checksum has drifted")
 )
 
 func main() {
@@ -57,18 +72,20 @@ func main() {
 		return
 	}
 
-	if *caCert != "" || *clientCert != "" || *clientKey != "" || *tlsName != "" {
-		if args[0] != "mysql" {
-			log.Fatal("cacert, clientcert, clientkey flags should only be set if the driver is mysql")
-		}
-
+	wantsTLS := *caCert != "" || *clientCert != "" || *clientKey != "" || *tlsName != "" || *sslMode != "" || *sslAllowInsecure
+	if wantsTLS && (args[0] == "mysql" || args[0] == "tidb") {
 		if (*caCert == "" || *tlsName == "") || ((*clientCert == "" || *clientKey == "") && *useClientCert) {
-			log.Fatal("cacert needs to always be set and client cert/key needs to be set if using clientcert")
+			if !*sslAllowInsecure {
+				log.Fatal("cacert needs to always be set and client cert/key needs to be set if using clientcert")
+			}
 		}
 
-		setupTLS()
+		setupMysqlTLS()
 	}
 
+	goose.SetLockTimeout(*lockTimeout)
+	goose.SetLockingDisabled(*noLock)
+
 	driver, dbstring, command := args[0], args[1], args[2]
 
 	if err := goose.SetDialect(driver); err != nil {
@@ -88,6 +105,10 @@ func main() {
 	default:
 	}
 
+	if wantsTLS && driver == "postgres" {
+		dbstring = appendPostgresTLSParams(dbstring)
+	}
+
 	db, err := sql.Open(driver, dbstring)
 	if err != nil {
 		log.Fatalf("-dbstring=%q: %v\n", dbstring, err)
@@ -98,7 +119,15 @@ func main() {
 		arguments = append(arguments, args[3:]...)
 	}
 
-	if err := goose.Run(command, db, *dir, arguments...); err != nil {
+	provider, err := goose.NewProvider(driver, db, *dir, goose.WithLockTimeout(*lockTimeout))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := runCommand(provider, command, arguments); err != nil {
+		if errors.Is(err, goose.ErrChecksumMismatch) {
+			os.Exit(2)
+		}
 		log.Fatalf("goose run: %v", err)
 	}
 }
@@ -145,34 +174,94 @@ Commands:
     version              Print the current version of the database
     create NAME [sql|go] Creates new migration file with the current timestamp
     fix                  Apply sequential ordering to migrations
+    verify               Re-hash on-disk migrations and report drift against the DB (exit 2 on drift)
 `
 )
 
-func setupTLS() {
-	// Load CA into cert pool
-	pemEncryptedCACert, err := ioutil.ReadFile(*caCert)
-	if err != nil {
-		log.Fatal(err)
+// setupMysqlTLS registers a named tls.Config with the mysql driver. The CA
+// cert is optional when -sslallowinsecure is set, since InsecureSkipVerify
+// makes server verification a no-op.
+func setupMysqlTLS() {
+	cfg := &tls.Config{
+		InsecureSkipVerify: *sslAllowInsecure,
 	}
 
-	rootCertPool := x509.NewCertPool()
-	if ok := rootCertPool.AppendCertsFromPEM(pemEncryptedCACert); !ok {
-		log.Fatal(err)
+	if *caCert != "" {
+		pemEncryptedCACert, err := ioutil.ReadFile(*caCert)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		rootCertPool := x509.NewCertPool()
+		if ok := rootCertPool.AppendCertsFromPEM(pemEncryptedCACert); !ok {
+			log.Fatal(err)
+		}
+		cfg.RootCAs = rootCertPool
 	}
-	clientCerts := make([]tls.Certificate, 0, 1)
+
 	if *useClientCert {
 		// Load cert/key into certificate
-		clientCert, err := tls.LoadX509KeyPair(*clientCert, *clientKey)
+		clientKeyPair, err := tls.LoadX509KeyPair(*clientCert, *clientKey)
 		if err != nil {
 			log.Fatal(err)
 		}
-		clientCerts = append(clientCerts, clientCert)
+		cfg.Certificates = []tls.Certificate{clientKeyPair}
 	}
+
 	// Create and register tls Config for use by mysql
-	mysql.RegisterTLSConfig(*tlsName, &tls.Config{
-		RootCAs:      rootCertPool,
-		Certificates: clientCerts,
-	})
+	mysql.RegisterTLSConfig(*tlsName, cfg)
 
 	log.Println("tls enabled")
 }
+
+// appendPostgresTLSParams translates the TLS flags into the sslmode/sslrootcert/
+// sslcert/sslkey libpq connection parameters and appends them to dbstring.
+// -sslallowinsecure maps to sslmode=require, trading server cert verification
+// for encrypted transport without pinning a CA.
+//
+// dbstring comes in two incompatible shapes lib/pq accepts: space-separated
+// key=value pairs ("user=postgres dbname=postgres"), and a postgres:// (or
+// postgresql://) URL - the shape used by the redshift example in usage.
+// Naively concatenating " sslmode=..." onto a URL lands inside the URL's
+// path/query instead of adding a parameter, so the two shapes need their own
+// merge strategy.
+func appendPostgresTLSParams(dbstring string) string {
+	mode := *sslMode
+	if mode == "" {
+		if *sslAllowInsecure {
+			mode = "require"
+		} else {
+			mode = "verify-full"
+		}
+	}
+
+	type param struct{ key, value string }
+	params := []param{{"sslmode", mode}}
+	if *caCert != "" {
+		params = append(params, param{"sslrootcert", *caCert})
+	}
+	if *useClientCert {
+		params = append(params, param{"sslcert", *clientCert}, param{"sslkey", *clientKey})
+	}
+
+	if strings.HasPrefix(dbstring, "postgres://") || strings.HasPrefix(dbstring, "postgresql://") {
+		u, err := url.Parse(dbstring)
+		if err != nil {
+			log.Fatalf("-dbstring=%q: %v\n", dbstring, err)
+		}
+
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p.key, p.value)
+		}
+		u.RawQuery = q.Encode()
+
+		return u.String()
+	}
+
+	kv := dbstring
+	for _, p := range params {
+		kv += fmt.Sprintf(" %s=%s", p.key, p.value)
+	}
+	return kv
+}
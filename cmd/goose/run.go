@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pressly/goose"
+)
+
+// runCommand dispatches a parsed CLI command to the Provider, honoring
+// -dry-run and -log-format/-format along the way.
+func runCommand(p *goose.Provider, command string, args []string) error {
+	switch command {
+	case "up":
+		if *dryRun {
+			return printPlan(p, true, func() (goose.Migrations, error) { return p.PlanUp() })
+		}
+		if err := checkStrict(p); err != nil {
+			return err
+		}
+		return printResults(p.Up())
+	case "up-by-one":
+		return printResults(p.UpByOne())
+	case "up-to":
+		version, err := parseVersion(args)
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			return printPlan(p, true, func() (goose.Migrations, error) { return p.PlanUpTo(version) })
+		}
+		if err := checkStrict(p); err != nil {
+			return err
+		}
+		return printResults(p.UpTo(version))
+	case "down":
+		if *dryRun {
+			return printPlan(p, false, func() (goose.Migrations, error) { return p.PlanDown() })
+		}
+		return printResults(p.Down())
+	case "down-to":
+		version, err := parseVersion(args)
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			return printPlan(p, false, func() (goose.Migrations, error) { return p.PlanDownTo(version) })
+		}
+		return printResults(p.DownTo(version))
+	case "redo":
+		return printResults(p.Redo())
+	case "reset":
+		return printResults(p.Reset())
+	case "status":
+		return printStatus(p)
+	case "verify":
+		return p.Verify()
+	case "version":
+		v, err := p.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("goose: version %v\n", v)
+		return nil
+	case "create":
+		if len(args) == 0 {
+			return fmt.Errorf("create must be of form: goose [OPTIONS] DRIVER DBSTRING create NAME [sql|go]")
+		}
+		migrationType := "go"
+		if len(args) >= 2 {
+			migrationType = args[1]
+		}
+		return p.Create(args[0], migrationType)
+	case "fix":
+		return p.Fix()
+	default:
+		return fmt.Errorf("%q: no such command", command)
+	}
+}
+
+// checkStrict refuses to let up/up-to proceed when -strict is set and any
+// already-applied migration's checksum has drifted from what's on disk.
+func checkStrict(p *goose.Provider) error {
+	if !*strict {
+		return nil
+	}
+
+	mismatches, err := p.VerifyChecksums()
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	for _, mm := range mismatches {
+		fmt.Printf("goose: DRIFT version %d (%s): recorded checksum %s, on-disk checksum %s\n",
+			mm.Version, mm.Source, mm.Expected, mm.Actual)
+	}
+	return goose.ErrChecksumMismatch
+}
+
+func parseVersion(args []string) (int64, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("expected a VERSION argument")
+	}
+	return strconv.ParseInt(args[0], 10, 64)
+}
+
+// printResults reports every applied migration's outcome, as text or as
+// one JSON event per line when -log-format=json.
+func printResults(results []*goose.MigrationResult, err error) error {
+	for _, r := range results {
+		if *logFormat == "json" {
+			b, merr := json.Marshal(r)
+			if merr != nil {
+				return merr
+			}
+			fmt.Println(string(b))
+			continue
+		}
+
+		status := "OK"
+		if r.Error != nil {
+			status = "FAILED"
+		}
+		fmt.Printf("goose: %s %s %s in %s\n", status, r.Direction, r.Source, r.Duration)
+	}
+	return err
+}
+
+// printPlan prints the migrations a -dry-run command would apply, in the
+// given direction, along with the SQL for .sql migrations, without applying
+// any of them. The plan functions read the current version from the DB,
+// which creates the version table if it does not exist yet.
+func printPlan(p *goose.Provider, direction bool, plan func() (goose.Migrations, error)) error {
+	migrations, err := plan()
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) == 0 {
+		fmt.Println("goose: dry run, no pending migrations")
+		return nil
+	}
+
+	fmt.Println("goose: dry run plan")
+	for _, m := range migrations {
+		fmt.Printf("  -- %d: %s\n", m.Version, m.Source)
+
+		up, down, err := p.ReadMigrationSQL(m)
+		if err != nil {
+			return err
+		}
+
+		statements := up
+		if !direction {
+			statements = down
+		}
+		for _, stmt := range statements {
+			fmt.Printf("     %s;\n", stmt)
+		}
+	}
+
+	return nil
+}
+
+// printStatus prints migration status as a table, or as JSON when
+// -format=json.
+func printStatus(p *goose.Provider) error {
+	if *format != "json" {
+		return p.Status()
+	}
+
+	entries, err := p.StatusEntries()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	return enc.Encode(entries)
+}
@@ -0,0 +1,104 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var sqlMigrationTemplate = `-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+`
+
+var goMigrationTemplate = `package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(up%[1]s, down%[1]s)
+}
+
+func up%[1]s(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	return nil
+}
+
+func down%[1]s(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	return nil
+}
+`
+
+// Create writes a new migration file to dir with the given name. db is
+// unused but accepted so Create matches the signature of the other
+// commands and can be dispatched from Run uniformly.
+func Create(db *sql.DB, dir, name, migrationType string) error {
+	version := time.Now().Format("20060102150405")
+	filename := fmt.Sprintf("%s_%s.%s", version, name, migrationType)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, filename)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create migration file: %w", err)
+	}
+	defer f.Close()
+
+	switch migrationType {
+	case "sql":
+		if _, err := f.WriteString(sqlMigrationTemplate); err != nil {
+			return err
+		}
+	case "go":
+		if _, err := fmt.Fprintf(f, goMigrationTemplate, camelCase(name)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown migration type %q, expected sql or go", migrationType)
+	}
+
+	fmt.Printf("goose: created %s\n", path)
+	return nil
+}
+
+func camelCase(name string) string {
+	out := make([]rune, 0, len(name))
+	upperNext := true
+	for _, r := range name {
+		if r == '_' || r == '-' || r == ' ' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			out = append(out, toUpper(r))
+			upperNext = false
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+func toUpper(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - ('a' - 'A')
+	}
+	return r
+}
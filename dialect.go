@@ -0,0 +1,225 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SqlDialect abstracts the goose_db_version queries that differ across
+// database engines. tableName is passed explicitly rather than read from a
+// package global so a Provider can target its own table without disturbing
+// any other Provider or the package-level API.
+type SqlDialect interface {
+	createVersionTableSQL(tableName string) string // sql string to create the db version table
+	insertVersionSQL(tableName string) string       // sql string to insert the initial version
+	deleteVersionSQL(tableName string) string       // sql string to delete version
+	migrationSQL(tableName string) string           // sql string to retrieve migrations
+	checksumSQL(tableName string) string            // sql string to retrieve a single migration's recorded checksum
+	dbVersionQuery(db *sql.DB, tableName string) (*sql.Rows, error)
+}
+
+var (
+	dialect            SqlDialect = &PostgresDialect{}
+	currentDialectName            = "postgres"
+)
+
+// resolveDialect returns the SqlDialect implementation for d without
+// touching the package-level dialect/currentDialectName globals, so a
+// Provider can hold its own dialect independent of the package-level API.
+func resolveDialect(d string) (SqlDialect, error) {
+	switch d {
+	case "postgres":
+		return &PostgresDialect{}, nil
+	case "mysql":
+		return &MySQLDialect{}, nil
+	case "sqlite3":
+		return &Sqlite3Dialect{}, nil
+	case "redshift":
+		return &RedshiftDialect{}, nil
+	case "tidb":
+		return &TiDBDialect{}, nil
+	default:
+		return nil, fmt.Errorf("%q: unknown dialect", d)
+	}
+}
+
+// SetDialect sets the package-level dialect used by the package-level API
+// (Run, Up, Down, ...). A Provider does not call this; it resolves and
+// holds its own dialect instead, via resolveDialect.
+func SetDialect(d string) error {
+	resolved, err := resolveDialect(d)
+	if err != nil {
+		return err
+	}
+	dialect = resolved
+	currentDialectName = d
+	return nil
+}
+
+////////////////////////////
+// Postgres
+////////////////////////////
+
+type PostgresDialect struct{}
+
+func (pg PostgresDialect) createVersionTableSQL(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+            	id serial NOT NULL,
+                version_id bigint NOT NULL,
+                is_applied boolean NOT NULL,
+                tstamp timestamp NULL default now(),
+                checksum text NULL,
+                PRIMARY KEY(id)
+            );`, tableName)
+}
+
+func (pg PostgresDialect) insertVersionSQL(tableName string) string {
+	return fmt.Sprintf("INSERT INTO %s (version_id, is_applied, checksum) VALUES ($1, $2, $3);", tableName)
+}
+
+func (pg PostgresDialect) dbVersionQuery(db *sql.DB, tableName string) (*sql.Rows, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT version_id, is_applied from %s ORDER BY id DESC", tableName))
+	if err != nil {
+		return nil, err
+	}
+	return rows, err
+}
+
+func (pg PostgresDialect) migrationSQL(tableName string) string {
+	return fmt.Sprintf("SELECT tstamp, is_applied FROM %s WHERE version_id=$1 ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (pg PostgresDialect) checksumSQL(tableName string) string {
+	return fmt.Sprintf("SELECT checksum FROM %s WHERE version_id=$1 AND is_applied=true ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (pg PostgresDialect) deleteVersionSQL(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version_id=$1;", tableName)
+}
+
+////////////////////////////
+// Redshift (Postgres-compatible, but no SERIAL type support)
+////////////////////////////
+
+type RedshiftDialect struct{}
+
+func (rs RedshiftDialect) createVersionTableSQL(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+            	id integer NOT NULL identity(1, 1),
+                version_id bigint NOT NULL,
+                is_applied boolean NOT NULL,
+                tstamp timestamp NULL default sysdate,
+                checksum varchar(64) NULL,
+                PRIMARY KEY(id)
+            );`, tableName)
+}
+
+func (rs RedshiftDialect) insertVersionSQL(tableName string) string {
+	return fmt.Sprintf("INSERT INTO %s (version_id, is_applied, checksum) VALUES ($1, $2, $3);", tableName)
+}
+
+func (rs RedshiftDialect) dbVersionQuery(db *sql.DB, tableName string) (*sql.Rows, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT version_id, is_applied from %s ORDER BY id DESC", tableName))
+	if err != nil {
+		return nil, err
+	}
+	return rows, err
+}
+
+func (rs RedshiftDialect) migrationSQL(tableName string) string {
+	return fmt.Sprintf("SELECT tstamp, is_applied FROM %s WHERE version_id=$1 ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (rs RedshiftDialect) checksumSQL(tableName string) string {
+	return fmt.Sprintf("SELECT checksum FROM %s WHERE version_id=$1 AND is_applied=true ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (rs RedshiftDialect) deleteVersionSQL(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version_id=$1;", tableName)
+}
+
+////////////////////////////
+// MySQL
+////////////////////////////
+
+type MySQLDialect struct{}
+
+func (m MySQLDialect) createVersionTableSQL(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+                id serial NOT NULL,
+                version_id bigint NOT NULL,
+                is_applied boolean NOT NULL,
+                tstamp timestamp NULL default now(),
+                checksum varchar(64) NULL,
+                PRIMARY KEY(id)
+            );`, tableName)
+}
+
+func (m MySQLDialect) insertVersionSQL(tableName string) string {
+	return fmt.Sprintf("INSERT INTO %s (version_id, is_applied, checksum) VALUES (?, ?, ?);", tableName)
+}
+
+func (m MySQLDialect) dbVersionQuery(db *sql.DB, tableName string) (*sql.Rows, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT version_id, is_applied from %s ORDER BY id DESC", tableName))
+	if err != nil {
+		return nil, err
+	}
+	return rows, err
+}
+
+func (m MySQLDialect) migrationSQL(tableName string) string {
+	return fmt.Sprintf("SELECT tstamp, is_applied FROM %s WHERE version_id=? ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (m MySQLDialect) checksumSQL(tableName string) string {
+	return fmt.Sprintf("SELECT checksum FROM %s WHERE version_id=? AND is_applied=true ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (m MySQLDialect) deleteVersionSQL(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version_id=?;", tableName)
+}
+
+// TiDBDialect behaves exactly like MySQL for goose's purposes.
+type TiDBDialect struct {
+	MySQLDialect
+}
+
+////////////////////////////
+// sqlite3
+////////////////////////////
+
+type Sqlite3Dialect struct{}
+
+func (m Sqlite3Dialect) createVersionTableSQL(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                version_id INTEGER NOT NULL,
+                is_applied INTEGER NOT NULL,
+                tstamp TIMESTAMP DEFAULT (datetime('now')),
+                checksum TEXT
+            );`, tableName)
+}
+
+func (m Sqlite3Dialect) insertVersionSQL(tableName string) string {
+	return fmt.Sprintf("INSERT INTO %s (version_id, is_applied, checksum) VALUES (?, ?, ?);", tableName)
+}
+
+func (m Sqlite3Dialect) dbVersionQuery(db *sql.DB, tableName string) (*sql.Rows, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT version_id, is_applied from %s ORDER BY id DESC", tableName))
+	if err != nil {
+		return nil, err
+	}
+	return rows, err
+}
+
+func (m Sqlite3Dialect) migrationSQL(tableName string) string {
+	return fmt.Sprintf("SELECT tstamp, is_applied FROM %s WHERE version_id=? ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (m Sqlite3Dialect) checksumSQL(tableName string) string {
+	return fmt.Sprintf("SELECT checksum FROM %s WHERE version_id=? AND is_applied=1 ORDER BY tstamp DESC LIMIT 1", tableName)
+}
+
+func (m Sqlite3Dialect) deleteVersionSQL(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version_id=?;", tableName)
+}
@@ -0,0 +1,52 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Down rolls back a single migration from the current version.
+func Down(db *sql.DB, dir string) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	currentMigration, err := migrations.Current(current)
+	if err != nil {
+		if err == ErrNoNextVersion {
+			fmt.Printf("goose: no migrations to run. current version: %d\n", current)
+			return nil
+		}
+		return err
+	}
+
+	return runMigration(db, currentMigration, false, dialect, TableName())
+}
+
+// DownTo rolls back migrations down to, but not including, the given
+// version.
+func DownTo(db *sql.DB, dir string, version int64) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrationsDownRange(migrations, current, version) {
+		if err := runMigration(db, m, false, dialect, TableName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package goose
+
+import "errors"
+
+// ErrNoNextVersion is returned when there is no next migration.
+var ErrNoNextVersion = errors.New("goose: no migrations to run")
+
+// ErrNoCurrentVersion describes a migration directory with no migration for
+// the current version. No function in this package returns it:
+// Migrations.Current reports that case with ErrNoNextVersion.
+var ErrNoCurrentVersion = errors.New("goose: no migrations found for current version")
@@ -0,0 +1,47 @@
+package goose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fix renames every .sql migration in dir so that its version is its
+// 1-based position among all migrations sorted by version, rewriting
+// filenames on disk. Registered Go migrations keep their version but still
+// count toward the positions. Useful after merging branches that each added
+// timestamp-based migrations out of order.
+func Fix(dir string) error {
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range migrations {
+		if m.Registered {
+			// Go migrations carry their version in a const/var inside the
+			// file; renumbering them would require rewriting source, which
+			// Fix does not attempt.
+			continue
+		}
+
+		newVersion := int64(i + 1)
+		if m.Version == newVersion {
+			continue
+		}
+
+		base := filepath.Base(m.Source)
+		idx := strings.Index(base, "_")
+		rest := base[idx+1:]
+
+		newPath := filepath.Join(filepath.Dir(m.Source), fmt.Sprintf("%05d_%s", newVersion, rest))
+		if err := os.Rename(m.Source, newPath); err != nil {
+			return fmt.Errorf("failed to rename %q to %q: %w", m.Source, newPath, err)
+		}
+
+		fmt.Printf("goose: renamed %s -> %s\n", m.Source, newPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package goose provides programmatic and command line support for SQL
+// schema migrations against Postgres, MySQL, sqlite3 and Redshift.
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migratingCommands acquire the distributed lock before touching the
+// migrations table, so concurrent goose invocations against the same
+// table serialize instead of racing.
+var migratingCommands = map[string]bool{
+	"up": true, "up-by-one": true, "up-to": true,
+	"down": true, "down-to": true,
+	"redo": true, "reset": true,
+}
+
+// Run runs a goose command, such as "up", "down", "status", etc, against
+// the given *sql.DB. dir is the directory holding the migration files. The
+// optional args are command-specific, e.g. a target version for "up-to".
+func Run(command string, db *sql.DB, dir string, args ...string) error {
+	runCommand := func() error {
+		switch command {
+		case "up":
+			return Up(db, dir)
+		case "up-by-one":
+			return UpByOne(db, dir)
+		case "up-to":
+			if len(args) == 0 {
+				return fmt.Errorf("up-to must be of form: goose [OPTIONS] DRIVER DBSTRING up-to VERSION")
+			}
+			version, err := versionArg(args[0])
+			if err != nil {
+				return err
+			}
+			return UpTo(db, dir, version)
+		case "create":
+			if len(args) == 0 {
+				return fmt.Errorf("create must be of form: goose [OPTIONS] DRIVER DBSTRING create NAME [sql|go]")
+			}
+			migrationType := "go"
+			if len(args) >= 2 {
+				migrationType = args[1]
+			}
+			return Create(db, dir, args[0], migrationType)
+		case "down":
+			return Down(db, dir)
+		case "down-to":
+			if len(args) == 0 {
+				return fmt.Errorf("down-to must be of form: goose [OPTIONS] DRIVER DBSTRING down-to VERSION")
+			}
+			version, err := versionArg(args[0])
+			if err != nil {
+				return err
+			}
+			return DownTo(db, dir, version)
+		case "redo":
+			return Redo(db, dir)
+		case "reset":
+			return Reset(db, dir)
+		case "status":
+			return Status(db, dir)
+		case "verify":
+			return Verify(db, dir)
+		case "version":
+			return Version(db, dir)
+		case "fix":
+			return Fix(dir)
+		default:
+			return fmt.Errorf("%q: no such command", command)
+		}
+	}
+
+	if migratingCommands[command] {
+		return withLock(db, currentDialectName, TableName(), runCommand)
+	}
+	return runCommand()
+}
+
+func versionArg(s string) (int64, error) {
+	var version int64
+	if _, err := fmt.Sscanf(s, "%d", &version); err != nil {
+		return 0, fmt.Errorf("invalid version %q: %w", s, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,178 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// Locker is a distributed advisory lock used to serialize concurrent goose
+// runs (e.g. several app replicas migrating on boot) against the same
+// migrations table. Lock must block until acquired or ctx is done; Unlock
+// releases a lock acquired on the same Locker.
+type Locker interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// lockKey derives a stable key from the migrations table name, so
+// independent goose schemas on the same server don't contend for the same
+// lock.
+func lockKey(tableName string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(tableName))
+	return int64(h.Sum64())
+}
+
+// NewLocker returns the Locker implementation appropriate for dialectName.
+// timeout bounds how long a mysql/tidb Locker's GET_LOCK call waits for the
+// lock server-side; zero means the mysqlLocker's own default (24h).
+func NewLocker(db *sql.DB, dialectName, tableName string, timeout time.Duration) (Locker, error) {
+	switch dialectName {
+	case "postgres", "redshift":
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &postgresLocker{conn: conn, key: lockKey(tableName)}, nil
+	case "mysql", "tidb":
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &mysqlLocker{conn: conn, name: fmt.Sprintf("goose.%d", lockKey(tableName)), timeout: timeout}, nil
+	case "sqlite3":
+		return &sqlite3Locker{}, nil
+	default:
+		return nil, fmt.Errorf("%q: no Locker implementation for this dialect", dialectName)
+	}
+}
+
+////////////////////////////
+// Postgres: pg_advisory_lock
+////////////////////////////
+
+type postgresLocker struct {
+	conn *sql.Conn
+	key  int64
+}
+
+func (l *postgresLocker) Lock(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, "select pg_advisory_lock($1)", l.key)
+	return err
+}
+
+func (l *postgresLocker) Unlock(ctx context.Context) error {
+	defer l.conn.Close()
+	_, err := l.conn.ExecContext(ctx, "select pg_advisory_unlock($1)", l.key)
+	return err
+}
+
+////////////////////////////
+// MySQL: GET_LOCK / RELEASE_LOCK
+////////////////////////////
+
+type mysqlLocker struct {
+	conn    *sql.Conn
+	name    string
+	timeout time.Duration
+}
+
+func (l *mysqlLocker) Lock(ctx context.Context) error {
+	timeout := l.timeout
+	if timeout <= 0 {
+		timeout = 24 * time.Hour
+	}
+
+	var acquired int
+	row := l.conn.QueryRowContext(ctx, "select GET_LOCK(?, ?)", l.name, int(timeout.Seconds()))
+	if err := row.Scan(&acquired); err != nil {
+		return err
+	}
+	if acquired != 1 {
+		return fmt.Errorf("goose: failed to acquire mysql lock %q within %s", l.name, timeout)
+	}
+	return nil
+}
+
+func (l *mysqlLocker) Unlock(ctx context.Context) error {
+	defer l.conn.Close()
+	_, err := l.conn.ExecContext(ctx, "select RELEASE_LOCK(?)", l.name)
+	return err
+}
+
+////////////////////////////
+// sqlite3: no-op
+////////////////////////////
+
+// sqlite3Locker is a no-op. An earlier version held a second write
+// transaction open on db as the "lock", but migrations are applied through
+// their own, separate transactions against the same *sql.DB - sqlite3 only
+// allows one writer for the whole file, so that second transaction just
+// blocked on itself until the driver's busy timeout expired. sqlite3 has no
+// concept of a second, independent process to serialize against in the
+// deployment shapes goose targets (it's a single file, usually owned by one
+// process), and it already serializes writers at the file level on its
+// own, so there is nothing useful left for this Locker to do.
+type sqlite3Locker struct{}
+
+func (l *sqlite3Locker) Lock(ctx context.Context) error   { return nil }
+func (l *sqlite3Locker) Unlock(ctx context.Context) error { return nil }
+
+////////////////////////////
+// package-level lock configuration, mirroring SetDialect/SetTableName
+////////////////////////////
+
+var (
+	lockTimeout time.Duration
+	lockingOff  bool
+)
+
+// SetLockTimeout bounds how long Run waits to acquire the migration lock
+// before giving up. Zero means wait indefinitely (subject to each dialect's
+// own ceiling, e.g. mysql's GET_LOCK caps at 24h here).
+func SetLockTimeout(d time.Duration) {
+	lockTimeout = d
+}
+
+// SetLockingDisabled turns off the advisory lock entirely, e.g. for a
+// single-process CI runner where contention can't happen.
+func SetLockingDisabled(disabled bool) {
+	lockingOff = disabled
+}
+
+// withLock acquires the migration lock for dialectName/tableName using the
+// package-level lock timeout (set via SetLockTimeout), runs fn, and
+// releases the lock afterwards, even if fn returns an error.
+func withLock(db *sql.DB, dialectName, tableName string, fn func() error) error {
+	return withLockTimeout(db, dialectName, tableName, lockTimeout, fn)
+}
+
+// withLockTimeout is withLock with an explicit timeout, used by Provider so
+// each instance can set its own via WithLockTimeout.
+func withLockTimeout(db *sql.DB, dialectName, tableName string, timeout time.Duration, fn func() error) error {
+	if lockingOff {
+		return fn()
+	}
+
+	locker, err := NewLocker(db, dialectName, tableName, timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := locker.Lock(ctx); err != nil {
+		return fmt.Errorf("goose: failed to acquire migration lock: %w", err)
+	}
+	defer locker.Unlock(context.Background())
+
+	return fn()
+}
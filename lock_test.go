@@ -0,0 +1,37 @@
+package goose
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSqlite3LockerIsNoOp(t *testing.T) {
+	l := &sqlite3Locker{}
+	ctx := context.Background()
+
+	if err := l.Lock(ctx); err != nil {
+		t.Fatalf("Lock returned error: %v", err)
+	}
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock returned error: %v", err)
+	}
+
+	// A real lock would block (or error) on a second acquisition without an
+	// intervening Unlock; sqlite3Locker must not, since it does nothing.
+	if err := l.Lock(ctx); err != nil {
+		t.Fatalf("second Lock returned error: %v", err)
+	}
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("second Unlock returned error: %v", err)
+	}
+}
+
+func TestNewLockerSqlite3(t *testing.T) {
+	l, err := NewLocker(nil, "sqlite3", "goose_db_version", 0)
+	if err != nil {
+		t.Fatalf("NewLocker returned error: %v", err)
+	}
+	if _, ok := l.(*sqlite3Locker); !ok {
+		t.Fatalf("NewLocker(sqlite3) returned %T, want *sqlite3Locker", l)
+	}
+}
@@ -0,0 +1,14 @@
+package goose
+
+import "log"
+
+// Logger is the logging interface used by Provider. It matches the subset
+// of *log.Logger that goose needs, so the standard logger satisfies it
+// without adaptation.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// defaultLogger wraps the standard library logger so Providers have
+// somewhere to send progress output when WithLogger is not supplied.
+var defaultLogger Logger = log.New(log.Writer(), "", log.LstdFlags)
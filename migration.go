@@ -0,0 +1,132 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrationRecord is a row in the goose_db_version table.
+type MigrationRecord struct {
+	VersionID int64
+	IsApplied bool // was this a result of up() or down()
+}
+
+// Migration represents a single migration, either a .sql file on disk or a
+// Go migration registered via AddMigration.
+type Migration struct {
+	Version    int64
+	Next       int64  // next version, or -1 if none
+	Previous   int64  // previous version, or -1 if none
+	Source     string // path to .sql script or .go file
+	Registered bool
+	UpFn       func(*sql.Tx) error // Go migration, up
+	DownFn     func(*sql.Tx) error // Go migration, down
+}
+
+func (m *Migration) String() string {
+	return m.Source
+}
+
+// Migrations is a collection of migrations, kept sorted by Version.
+type Migrations []*Migration
+
+func (ms Migrations) Len() int      { return len(ms) }
+func (ms Migrations) Swap(i, j int) { ms[i], ms[j] = ms[j], ms[i] }
+func (ms Migrations) Less(i, j int) bool {
+	if ms[i].Version == ms[j].Version {
+		panic(fmt.Sprintf("goose: duplicate version %d detected:\n%v\n%v", ms[i].Version, ms[i].Source, ms[j].Source))
+	}
+	return ms[i].Version < ms[j].Version
+}
+
+func (ms Migrations) sort() {
+	sort.Sort(ms)
+
+	// now that we're sorted in the appropriate direction,
+	// populate next and previous for each migration
+	for i, m := range ms {
+		prev := int64(-1)
+		if i > 0 {
+			prev = ms[i-1].Version
+			ms[i-1].Next = m.Version
+		}
+		m.Previous = prev
+	}
+	if len(ms) > 0 {
+		ms[len(ms)-1].Next = -1
+	}
+}
+
+// Current returns the migration whose version equals current, or
+// ErrNoNextVersion if there is none.
+func (ms Migrations) Current(current int64) (*Migration, error) {
+	for i, m := range ms {
+		if m.Version == current {
+			return ms[i], nil
+		}
+	}
+
+	return nil, ErrNoNextVersion
+}
+
+// Next returns the next migration after current, or ErrNoNextVersion.
+func (ms Migrations) Next(current int64) (*Migration, error) {
+	for i, m := range ms {
+		if m.Version > current {
+			return ms[i], nil
+		}
+	}
+
+	return nil, ErrNoNextVersion
+}
+
+// Previous returns the last migration before current, or ErrNoNextVersion.
+func (ms Migrations) Previous(current int64) (*Migration, error) {
+	var m *Migration
+
+	for i := len(ms) - 1; i >= 0; i-- {
+		if ms[i].Version < current {
+			m = ms[i]
+			break
+		}
+	}
+
+	if m == nil {
+		return nil, ErrNoNextVersion
+	}
+
+	return m, nil
+}
+
+// Last returns the last migration in the collection.
+func (ms Migrations) Last() (*Migration, error) {
+	if len(ms) == 0 {
+		return nil, ErrNoNextVersion
+	}
+
+	return ms[len(ms)-1], nil
+}
+
+func numericComponent(name string) (int64, error) {
+	base := filepath.Base(name)
+
+	if ext := filepath.Ext(base); ext != ".go" && ext != ".sql" {
+		return 0, fmt.Errorf("goose: failed to parse migration filename %q: must end in .sql or .go", name)
+	}
+
+	idx := strings.Index(base, "_")
+	if idx < 0 {
+		return 0, fmt.Errorf("goose: failed to parse migration filename %q: must be prefixed with a timestamp or sequence number and an underscore", name)
+	}
+
+	n, err := strconv.ParseInt(base[:idx], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
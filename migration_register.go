@@ -0,0 +1,65 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+)
+
+// registeredGoMigrations holds every migration registered via AddMigration,
+// keyed by the caller's source file so duplicate registrations are caught.
+var registeredGoMigrations = make(map[string]*Migration)
+
+// AddMigration registers a Go migration identified by the file that calls
+// it (normally from that migration's init()). up and down may be nil for a
+// migration that only supports one direction.
+func AddMigration(up, down func(*sql.Tx) error) {
+	_, filename, _, _ := runtime.Caller(1)
+	AddNamedMigration(filename, up, down)
+}
+
+// AddNamedMigration works like AddMigration but allows an explicit filename,
+// useful when migrations are registered from somewhere other than their own
+// init(), e.g. a generated registry.
+func AddNamedMigration(filename string, up, down func(*sql.Tx) error) {
+	v, err := numericComponent(filename)
+	if err != nil {
+		panic(err)
+	}
+
+	if existing, ok := registeredGoMigrations[filename]; ok {
+		panic(fmt.Sprintf("goose: duplicate migration %v detected: %v", v, existing.Source))
+	}
+
+	registeredGoMigrations[filename] = &Migration{
+		Version:    v,
+		Source:     filename,
+		Registered: true,
+		UpFn:       up,
+		DownFn:     down,
+	}
+}
+
+// runGoMigration executes a registered Go migration inside a transaction.
+func runGoMigration(db *sql.DB, m *Migration, direction bool) error {
+	fn := m.UpFn
+	if !direction {
+		fn = m.DownFn
+	}
+	if fn == nil {
+		// Migration has no-op in this direction.
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to run go migration %q: %w", m.Source, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+package goose
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// collectMigrations returns all migrations found on disk in dir, merged
+// with any Go migrations registered via AddMigration, sorted by version.
+func collectMigrations(dir string) (Migrations, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s directory does not exist", dir)
+	}
+
+	var migrations Migrations
+
+	// Sql migrations on disk.
+	sqlMigrations, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range sqlMigrations {
+		v, err := numericComponent(file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, &Migration{Version: v, Source: file, Registered: false})
+	}
+
+	// Go migrations registered via AddMigration, keyed by source filename.
+	for _, m := range registeredGoMigrations {
+		migrations = append(migrations, m)
+	}
+
+	migrations.sort()
+
+	return migrations, nil
+}
+
+// collectMigrationsFS is the fs.FS equivalent of collectMigrations, for
+// Providers configured with WithFS (e.g. an embed.FS of migrations baked
+// into the binary).
+func collectMigrationsFS(fsys fs.FS, dir string) (Migrations, error) {
+	var migrations Migrations
+
+	sqlMigrations, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range sqlMigrations {
+		v, err := numericComponent(file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, &Migration{Version: v, Source: file, Registered: false})
+	}
+
+	for _, m := range registeredGoMigrations {
+		migrations = append(migrations, m)
+	}
+
+	migrations.sort()
+
+	return migrations, nil
+}
+
+// migrationsUpRange returns every migration with current < version <= to,
+// ordered oldest first.
+func migrationsUpRange(migrations Migrations, current, to int64) Migrations {
+	var up Migrations
+	for _, m := range migrations {
+		if m.Version > current && m.Version <= to {
+			up = append(up, m)
+		}
+	}
+	return up
+}
+
+// migrationsDownRange returns every applied migration with to < version <= current,
+// ordered newest first (the order they must be rolled back in).
+func migrationsDownRange(migrations Migrations, current, to int64) Migrations {
+	var down Migrations
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= current && m.Version > to {
+			down = append(down, m)
+		}
+	}
+	return down
+}
@@ -0,0 +1,441 @@
+package goose
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MigrationResult describes the outcome of applying or rolling back a
+// single migration via a Provider method.
+type MigrationResult struct {
+	Version   int64
+	Source    string
+	Direction string // "up" or "down"
+	Duration  time.Duration
+	Error     error
+}
+
+// Name returns the migration's name: its Source's base filename, stripped
+// of the leading version number and the extension.
+func (r *MigrationResult) Name() string {
+	base := strings.TrimSuffix(filepath.Base(r.Source), filepath.Ext(r.Source))
+	if idx := strings.Index(base, "_"); idx >= 0 {
+		return base[idx+1:]
+	}
+	return base
+}
+
+// MarshalJSON renders a MigrationResult as the one-event-per-migration
+// shape consumed by "-log-format=json": version, name, direction,
+// duration_ms, source, error.
+func (r *MigrationResult) MarshalJSON() ([]byte, error) {
+	errMsg := ""
+	if r.Error != nil {
+		errMsg = r.Error.Error()
+	}
+
+	return json.Marshal(struct {
+		Version    int64  `json:"version"`
+		Name       string `json:"name"`
+		Direction  string `json:"direction"`
+		DurationMs int64  `json:"duration_ms"`
+		Source     string `json:"source"`
+		Error      string `json:"error,omitempty"`
+	}{
+		Version:    r.Version,
+		Name:       r.Name(),
+		Direction:  r.Direction,
+		DurationMs: r.Duration.Milliseconds(),
+		Source:     r.Source,
+		Error:      errMsg,
+	})
+}
+
+// Provider drives migrations against db programmatically, without going
+// through the goose CLI. Unlike the package-level Run, it returns
+// structured results instead of printing to stdout, and holds its own
+// dialect and table name rather than the package-level globals Run uses,
+// so a process can manage more than one migration set (e.g. one Provider
+// per tenant schema) without one Provider's configuration stomping
+// another's.
+type Provider struct {
+	db           *sql.DB
+	dialectName  string
+	dialectImpl  SqlDialect
+	dir          string
+	fsys         fs.FS
+	tableName    string
+	logger       Logger
+	lockTimeout  time.Duration
+	noVersioning bool
+}
+
+// ProviderOption configures a Provider constructed via NewProvider.
+type ProviderOption func(*Provider)
+
+// WithFS sources migrations from fsys instead of the disk; the Provider's
+// dir is then read as a path inside fsys. This allows migrations to be
+// embedded in the binary via embed.FS.
+func WithFS(fsys fs.FS) ProviderOption {
+	return func(p *Provider) { p.fsys = fsys }
+}
+
+// WithTableName overrides the default goose_db_version table name.
+func WithTableName(name string) ProviderOption {
+	return func(p *Provider) { p.tableName = name }
+}
+
+// WithLogger overrides the Provider's default logger.
+func WithLogger(l Logger) ProviderOption {
+	return func(p *Provider) { p.logger = l }
+}
+
+// WithLockTimeout bounds how long the Provider waits to acquire its
+// migration lock before giving up.
+func WithLockTimeout(d time.Duration) ProviderOption {
+	return func(p *Provider) { p.lockTimeout = d }
+}
+
+// WithNoVersioning runs pending migrations without recording them in the
+// goose_db_version table, useful for one-off environments like tests that
+// reset their schema between runs.
+func WithNoVersioning() ProviderOption {
+	return func(p *Provider) { p.noVersioning = true }
+}
+
+// NewProvider constructs a Provider for the given dialect ("postgres",
+// "mysql", "sqlite3", "redshift", "tidb"), applying opts in order. Unlike
+// SetDialect/SetTableName, this never touches package-level state, so
+// multiple Providers (e.g. against different tenant databases) can coexist
+// safely in the same process.
+func NewProvider(dialectName string, db *sql.DB, dir string, opts ...ProviderOption) (*Provider, error) {
+	d, err := resolveDialect(dialectName)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		db:          db,
+		dialectName: dialectName,
+		dialectImpl: d,
+		dir:         dir,
+		tableName:   defaultTableName,
+		logger:      defaultLogger,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+func (p *Provider) collect() (Migrations, error) {
+	if p.fsys != nil {
+		return collectMigrationsFS(p.fsys, p.dir)
+	}
+	return collectMigrations(p.dir)
+}
+
+// run applies the migrations returned by selectFn, in order, stopping at the
+// first failure. Every migration it ran, including the one that failed, gets
+// a MigrationResult. The migration lock is held for the duration of the run.
+func (p *Provider) run(selectFn func(Migrations) Migrations, direction bool) ([]*MigrationResult, error) {
+	var results []*MigrationResult
+
+	err := withLockTimeout(p.db, p.dialectName, p.tableName, p.lockTimeout, func() error {
+		var runErr error
+		results, runErr = p.runLocked(selectFn, direction)
+		return runErr
+	})
+
+	return results, err
+}
+
+func (p *Provider) runLocked(selectFn func(Migrations) Migrations, direction bool) ([]*MigrationResult, error) {
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*MigrationResult
+	for _, m := range selectFn(migrations) {
+		start := time.Now()
+
+		var runErr error
+		switch {
+		case p.noVersioning && m.Registered:
+			runErr = runGoMigration(p.db, m, direction)
+		case p.noVersioning:
+			runErr = runSQLMigrationFS(p.fsys, p.db, m.Source, direction)
+		case m.Registered:
+			if err := runGoMigration(p.db, m, direction); err != nil {
+				runErr = err
+			} else if err := recordVersion(p.db, p.fsys, m, direction, p.dialectImpl, p.tableName); err != nil {
+				runErr = err
+			}
+		default:
+			if err := runSQLMigrationFS(p.fsys, p.db, m.Source, direction); err != nil {
+				runErr = err
+			} else if err := recordVersion(p.db, p.fsys, m, direction, p.dialectImpl, p.tableName); err != nil {
+				runErr = err
+			}
+		}
+
+		res := &MigrationResult{
+			Version:   m.Version,
+			Source:    m.Source,
+			Direction: direction2str(direction),
+			Duration:  time.Since(start),
+			Error:     runErr,
+		}
+		results = append(results, res)
+		p.logger.Printf("goose: %s %s in %s", res.Direction, res.Source, res.Duration)
+
+		if runErr != nil {
+			return results, fmt.Errorf("failed to run migration %s: %w", m.Source, runErr)
+		}
+	}
+
+	return results, nil
+}
+
+// PlanUp returns, in order, the migrations Up would apply, without running
+// any of them. It still reads the current version from the DB to know
+// where the plan starts, and that read creates the version table if it does
+// not exist yet; the same holds for PlanUpTo, PlanDown and PlanDownTo.
+func (p *Provider) PlanUp() (Migrations, error) {
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		if err == ErrNoNextVersion {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return p.PlanUpTo(last.Version)
+}
+
+// PlanUpTo is UpTo's dry-run counterpart.
+func (p *Provider) PlanUpTo(version int64) (Migrations, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationsUpRange(migrations, current, version), nil
+}
+
+// PlanDown is Down's dry-run counterpart.
+func (p *Provider) PlanDown() (Migrations, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+	return p.PlanDownTo(current - 1)
+}
+
+// PlanDownTo is DownTo's dry-run counterpart.
+func (p *Provider) PlanDownTo(version int64) (Migrations, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationsDownRange(migrations, current, version), nil
+}
+
+// Up migrates the DB to the most recent version available.
+func (p *Provider) Up() ([]*MigrationResult, error) {
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		if err == ErrNoNextVersion {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return p.UpTo(last.Version)
+}
+
+// UpTo migrates the DB up to, and including, version.
+func (p *Provider) UpTo(version int64) ([]*MigrationResult, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.run(func(ms Migrations) Migrations {
+		return migrationsUpRange(ms, current, version)
+	}, true)
+}
+
+// UpByOne migrates the DB up by a single version.
+func (p *Provider) UpByOne() ([]*MigrationResult, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := migrations.Next(current)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.run(func(ms Migrations) Migrations {
+		return Migrations{next}
+	}, true)
+}
+
+// Down rolls back a single migration from the current version.
+func (p *Provider) Down() ([]*MigrationResult, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.DownTo(current - 1)
+}
+
+// DownTo rolls back migrations down to, but not including, version.
+func (p *Provider) DownTo(version int64) ([]*MigrationResult, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.run(func(ms Migrations) Migrations {
+		return migrationsDownRange(ms, current, version)
+	}, false)
+}
+
+// Redo rolls back the most recently applied migration, then re-applies it.
+func (p *Provider) Redo() ([]*MigrationResult, error) {
+	current, err := getDBVersion(p.db, p.dialectImpl, p.tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := p.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrations.Current(current)
+	if err != nil {
+		return nil, err
+	}
+
+	down, err := p.run(func(ms Migrations) Migrations { return Migrations{m} }, false)
+	if err != nil {
+		return down, err
+	}
+
+	up, err := p.run(func(ms Migrations) Migrations { return Migrations{m} }, true)
+	return append(down, up...), err
+}
+
+// Reset rolls back every applied migration.
+func (p *Provider) Reset() ([]*MigrationResult, error) {
+	return p.DownTo(0)
+}
+
+// Status reports the current state of every migration in dir.
+func (p *Provider) Status() error {
+	entries, err := p.StatusEntries()
+	if err != nil {
+		return err
+	}
+	return printStatusTable(entries)
+}
+
+// StatusEntries is Status without the table-printing, for callers (e.g. a
+// -format=json CLI flag) that want the data rather than stdout output.
+func (p *Provider) StatusEntries() ([]StatusEntry, error) {
+	return collectStatus(p.fsys, p.db, p.dir, p.dialectImpl, p.tableName)
+}
+
+// Verify re-hashes every on-disk migration and compares it against the
+// checksum recorded in the DB, printing any drift it finds. It returns
+// ErrChecksumMismatch if any migration has drifted.
+func (p *Provider) Verify() error {
+	mismatches, err := p.VerifyChecksums()
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		fmt.Println("goose: OK, no checksum drift detected")
+		return nil
+	}
+
+	for _, mm := range mismatches {
+		fmt.Printf("goose: DRIFT version %d (%s): recorded checksum %s, on-disk checksum %s\n",
+			mm.Version, mm.Source, mm.Expected, mm.Actual)
+	}
+
+	return ErrChecksumMismatch
+}
+
+// VerifyChecksums is Verify without the printing, for callers (e.g. the
+// -strict flag) that want to inspect drift before deciding what to do.
+func (p *Provider) VerifyChecksums() ([]ChecksumMismatch, error) {
+	return verifyChecksumsFS(p.fsys, p.db, p.dir, p.dialectImpl, p.tableName)
+}
+
+// ReadMigrationSQL reads m's up/down statement blocks without running them,
+// for callers (e.g. -dry-run) that want to show the SQL a migration would
+// execute. For Go migrations, which have no SQL to show, it returns no
+// statements and a nil error.
+func (p *Provider) ReadMigrationSQL(m *Migration) (up, down []string, err error) {
+	if m.Registered {
+		return nil, nil, nil
+	}
+	return ReadSQLMigration(p.fsys, m.Source)
+}
+
+// Version returns the current database version.
+func (p *Provider) Version() (int64, error) {
+	return getDBVersion(p.db, p.dialectImpl, p.tableName)
+}
+
+// Create writes a new migration file to dir.
+func (p *Provider) Create(name, migrationType string) error {
+	return Create(p.db, p.dir, name, migrationType)
+}
+
+// Fix renumbers the .sql migrations in dir sequentially, as the
+// package-level Fix does.
+func (p *Provider) Fix() error {
+	return Fix(p.dir)
+}
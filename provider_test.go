@@ -0,0 +1,152 @@
+package goose
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *sql.DB) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeMigration := func(name, up, down string) {
+		content := "-- +goose Up\n" + up + "\n-- +goose Down\n" + down + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	writeMigration("00001_create_foo.sql", "CREATE TABLE foo (id INTEGER PRIMARY KEY);", "DROP TABLE foo;")
+	writeMigration("00002_create_bar.sql", "CREATE TABLE bar (id INTEGER PRIMARY KEY);", "DROP TABLE bar;")
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	p, err := NewProvider("sqlite3", db, dir)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	return p, db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var got string
+	err := db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", name).Scan(&got)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		t.Fatalf("querying sqlite_master: %v", err)
+	}
+	return true
+}
+
+func TestProviderUpDownStatus(t *testing.T) {
+	p, db := newTestProvider(t)
+
+	results, err := p.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Up applied %d migrations, want 2", len(results))
+	}
+	if !tableExists(t, db, "foo") || !tableExists(t, db, "bar") {
+		t.Fatal("Up did not create both tables")
+	}
+
+	version, err := p.Version()
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if version != 2 {
+		t.Fatalf("Version = %d, want 2", version)
+	}
+
+	entries, err := p.StatusEntries()
+	if err != nil {
+		t.Fatalf("StatusEntries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("StatusEntries returned %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if !e.Applied {
+			t.Errorf("version %d: Applied = false, want true", e.Version)
+		}
+	}
+
+	if _, err := p.Down(); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if tableExists(t, db, "bar") {
+		t.Fatal("Down did not roll back bar")
+	}
+	if !tableExists(t, db, "foo") {
+		t.Fatal("Down rolled back more than one migration")
+	}
+
+	version, err = p.Version()
+	if err != nil {
+		t.Fatalf("Version after Down: %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("Version after Down = %d, want 1", version)
+	}
+}
+
+func TestProviderIsolatedFromOtherProviders(t *testing.T) {
+	// Regression test for the dialect/table-name isolation bug fixed in
+	// 2fc8d9d: constructing a second Provider for a different tenant must
+	// not repoint the first Provider's table name.
+	dirA, dirB := t.TempDir(), t.TempDir()
+	write := func(dir string) {
+		content := "-- +goose Up\nCREATE TABLE t (id INTEGER PRIMARY KEY);\n-- +goose Down\nDROP TABLE t;\n"
+		if err := os.WriteFile(filepath.Join(dir, "00001_create_t.sql"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write(dirA)
+	write(dirB)
+
+	dbA, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "a.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer dbA.Close()
+
+	pA, err := NewProvider("sqlite3", dbA, dirA, WithTableName("tenant_a_version"))
+	if err != nil {
+		t.Fatalf("NewProvider pA: %v", err)
+	}
+
+	dbB, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "b.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer dbB.Close()
+
+	if _, err := NewProvider("sqlite3", dbB, dirB, WithTableName("tenant_b_version")); err != nil {
+		t.Fatalf("NewProvider pB: %v", err)
+	}
+
+	if _, err := pA.Up(); err != nil {
+		t.Fatalf("pA.Up: %v", err)
+	}
+
+	if !tableExists(t, dbA, "tenant_a_version") {
+		t.Fatal("pA did not record its version in tenant_a_version")
+	}
+	if tableExists(t, dbA, "tenant_b_version") {
+		t.Fatal("pA created tenant_b_version in dbA; Provider table names are leaking across instances")
+	}
+}
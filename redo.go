@@ -0,0 +1,27 @@
+package goose
+
+import "database/sql"
+
+// Redo rolls back the most recently applied migration, then re-applies it.
+func Redo(db *sql.DB, dir string) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	currentMigration, err := migrations.Current(current)
+	if err != nil {
+		return err
+	}
+
+	if err := runMigration(db, currentMigration, false, dialect, TableName()); err != nil {
+		return err
+	}
+
+	return runMigration(db, currentMigration, true, dialect, TableName())
+}
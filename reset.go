@@ -0,0 +1,8 @@
+package goose
+
+import "database/sql"
+
+// Reset rolls back all applied migrations.
+func Reset(db *sql.DB, dir string) error {
+	return DownTo(db, dir, 0)
+}
@@ -0,0 +1,226 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+)
+
+// execer is the subset of *sql.DB / *sql.Tx / *sql.Conn that RunTx and
+// RunConn need to read and write the goose_db_version table.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// RunTx runs a goose command against fsys using tx, so migrations
+// piggy-back on a transaction the caller already owns (e.g. so they share
+// session-scoped settings, like a Postgres `SET lock_timeout`, with the
+// rest of the caller's work). Unlike Run, the migrations table is updated
+// using tx directly and tx is never committed or rolled back here; that
+// remains the caller's responsibility. Only "up", "up-to", "down" and
+// "down-to" are supported; any other command returns an error. "create" and
+// "fix" operate on disk, not a fs.FS, and should go through Run instead.
+//
+// RunTx does not acquire the migration Locker that Run/Provider use, since
+// it only receives a *sql.Tx, not the *sql.DB a Locker needs to open its
+// own advisory-lock connection. Callers running RunTx concurrently with
+// Run or a Provider against the same table are responsible for their own
+// serialization (e.g. by holding the caller's own lock before starting
+// tx).
+func RunTx(command string, tx *sql.Tx, fsys fs.FS, args ...string) error {
+	ctx := context.Background()
+	return runFS(ctx, tx, fsys, command, args, func(m *Migration, dir bool) error {
+		return applyMigration(ctx, tx, fsys, m, dir)
+	})
+}
+
+// RunConn is RunTx for a single pinned *sql.Conn rather than an existing
+// transaction, e.g. the TLS-wrapped connection built by a CLI's own TLS
+// setup. Each migration runs in its own transaction scoped to conn, same
+// as Run, but conn itself stays open and pinned across the whole run.
+//
+// Like RunTx, RunConn does not acquire the migration Locker: it only
+// receives a *sql.Conn, not the *sql.DB a Locker needs. The same caller
+// responsibility for serialization applies.
+func RunConn(command string, conn *sql.Conn, fsys fs.FS, args ...string) error {
+	ctx := context.Background()
+	return runFS(ctx, conn, fsys, command, args, func(m *Migration, dir bool) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := applyMigration(ctx, tx, fsys, m, dir); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// runFS plans the set of migrations a command needs, in order, and hands
+// each one plus its direction to apply.
+func runFS(ctx context.Context, ex execer, fsys fs.FS, command string, args []string, apply func(*Migration, bool) error) error {
+	migrations, err := collectMigrationsFS(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	current, err := dbVersionExecer(ctx, ex)
+	if err != nil {
+		return err
+	}
+
+	var plan Migrations
+	direction := true
+
+	switch command {
+	case "up":
+		last, err := migrations.Last()
+		if err != nil {
+			if err == ErrNoNextVersion {
+				return nil
+			}
+			return err
+		}
+		plan = migrationsUpRange(migrations, current, last.Version)
+	case "up-to":
+		version, err := versionArgs(args)
+		if err != nil {
+			return err
+		}
+		plan = migrationsUpRange(migrations, current, version)
+	case "down":
+		m, err := migrations.Current(current)
+		if err != nil {
+			if err == ErrNoNextVersion {
+				return nil
+			}
+			return err
+		}
+		plan, direction = Migrations{m}, false
+	case "down-to":
+		version, err := versionArgs(args)
+		if err != nil {
+			return err
+		}
+		plan, direction = migrationsDownRange(migrations, current, version), false
+	default:
+		return fmt.Errorf("%q: command not supported against an existing tx/conn, use Run", command)
+	}
+
+	if err := ensureChecksumColumnExecer(ctx, ex); err != nil {
+		return err
+	}
+
+	for _, m := range plan {
+		if err := apply(m, direction); err != nil {
+			return fmt.Errorf("failed to apply migration %v: %w", m.Source, err)
+		}
+
+		sum, err := checksumMigration(fsys, m)
+		if err != nil {
+			return err
+		}
+		if _, err := ex.ExecContext(ctx, dialect.insertVersionSQL(TableName()), m.Version, direction, sum); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureChecksumColumnExecer is ensureChecksumColumn for an execer instead
+// of a concrete *sql.DB, used by RunTx/RunConn.
+func ensureChecksumColumnExecer(ctx context.Context, ex execer) error {
+	_, err := ex.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN checksum TEXT", TableName()))
+	if err != nil && !isDuplicateColumnErr(err) {
+		return err
+	}
+	return nil
+}
+
+func versionArgs(args []string) (int64, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("expected a VERSION argument")
+	}
+	return versionArg(args[0])
+}
+
+// applyMigration runs a single migration's up or down side using tx,
+// reading .sql migrations from fsys rather than the filesystem.
+func applyMigration(ctx context.Context, tx *sql.Tx, fsys fs.FS, m *Migration, direction bool) error {
+	if m.Registered {
+		fn := m.UpFn
+		if !direction {
+			fn = m.DownFn
+		}
+		if fn == nil {
+			return nil
+		}
+		return fn(tx)
+	}
+
+	f, err := fsys.Open(m.Source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	up, down, err := parseSQLMigration(f)
+	if err != nil {
+		return err
+	}
+
+	statements := up
+	if !direction {
+		statements = down
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dbVersionExecer is GetDBVersion for an execer instead of a concrete
+// *sql.DB, used by RunTx/RunConn which don't get their own *sql.DB to
+// create the version table against.
+func dbVersionExecer(ctx context.Context, ex execer) (int64, error) {
+	rows, err := ex.QueryContext(ctx, fmt.Sprintf("SELECT version_id, is_applied from %s ORDER BY id DESC", TableName()))
+	if err != nil {
+		return 0, fmt.Errorf("goose: %s table not found; RunTx/RunConn require it to already exist (create it via Run first): %w", TableName(), err)
+	}
+	defer rows.Close()
+
+	toSkip := make([]int64, 0)
+	for rows.Next() {
+		var row MigrationRecord
+		if err := rows.Scan(&row.VersionID, &row.IsApplied); err != nil {
+			return 0, err
+		}
+
+		skip := false
+		for _, v := range toSkip {
+			if v == row.VersionID {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		if row.IsApplied {
+			return row.VersionID, nil
+		}
+		toSkip = append(toSkip, row.VersionID)
+	}
+
+	return 0, nil
+}
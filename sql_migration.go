@@ -0,0 +1,141 @@
+package goose
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+const (
+	sqlCmdPrefixUp   = "-- +goose Up"
+	sqlCmdPrefixDown = "-- +goose Down"
+)
+
+// parseSQLMigration splits a .sql migration file into its up and down
+// statement blocks, delimited by the "-- +goose Up" / "-- +goose Down"
+// marker comments. Statements within a block are split on ";".
+func parseSQLMigration(r io.Reader) (up []string, down []string, err error) {
+	scanner := bufio.NewScanner(r)
+
+	var buf strings.Builder
+	section := ""
+
+	flush := func() {
+		stmts := splitStatements(buf.String())
+		switch section {
+		case sqlCmdPrefixUp:
+			up = append(up, stmts...)
+		case sqlCmdPrefixDown:
+			down = append(down, stmts...)
+		}
+		buf.Reset()
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, sqlCmdPrefixUp):
+			flush()
+			section = sqlCmdPrefixUp
+			continue
+		case strings.HasPrefix(trimmed, sqlCmdPrefixDown):
+			flush()
+			section = sqlCmdPrefixDown
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return up, down, nil
+}
+
+func splitStatements(block string) []string {
+	var stmts []string
+	for _, s := range strings.Split(block, ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		stmts = append(stmts, s)
+	}
+	return stmts
+}
+
+// ReadSQLMigration reads and parses the .sql file at path into its up and
+// down statement blocks without executing anything, for callers (e.g.
+// -dry-run) that want to show the SQL a migration would run. A nil fsys
+// reads from disk.
+func ReadSQLMigration(fsys fs.FS, path string) (up, down []string, err error) {
+	var f fs.File
+	if fsys != nil {
+		f, err = fsys.Open(path)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open SQL migration %q: %w", path, err)
+	}
+	defer f.Close()
+
+	return parseSQLMigration(f)
+}
+
+// runSQLMigration reads the .sql file at path off disk and runs its up or
+// down block inside a single transaction.
+func runSQLMigration(db *sql.DB, path string, direction bool) error {
+	return runSQLMigrationFS(nil, db, path, direction)
+}
+
+// runSQLMigrationFS is runSQLMigration sourcing the file from fsys instead
+// of the filesystem, for Providers configured with WithFS. A nil fsys
+// falls back to os.Open.
+func runSQLMigrationFS(fsys fs.FS, db *sql.DB, path string, direction bool) error {
+	var f fs.File
+	var err error
+	if fsys != nil {
+		f, err = fsys.Open(path)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open SQL migration %q: %w", path, err)
+	}
+	defer f.Close()
+
+	up, down, err := parseSQLMigration(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse SQL migration %q: %w", path, err)
+	}
+
+	statements := up
+	if !direction {
+		statements = down
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to run SQL migration %q: %w", path, err)
+		}
+	}
+
+	return tx.Commit()
+}
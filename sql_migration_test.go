@@ -0,0 +1,35 @@
+package goose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSQLMigration(t *testing.T) {
+	const migration = `-- +goose Up
+CREATE TABLE foo (id int);
+INSERT INTO foo (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE foo;
+`
+
+	up, down, err := parseSQLMigration(strings.NewReader(migration))
+	if err != nil {
+		t.Fatalf("parseSQLMigration returned error: %v", err)
+	}
+
+	if len(up) != 2 {
+		t.Fatalf("expected 2 up statements, got %d: %v", len(up), up)
+	}
+	if up[0] != "CREATE TABLE foo (id int)" {
+		t.Errorf("unexpected first up statement: %q", up[0])
+	}
+
+	if len(down) != 1 {
+		t.Fatalf("expected 1 down statement, got %d: %v", len(down), down)
+	}
+	if down[0] != "DROP TABLE foo" {
+		t.Errorf("unexpected down statement: %q", down[0])
+	}
+}
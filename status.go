@@ -0,0 +1,114 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+)
+
+// StatusEntry describes one migration's applied/pending state, as reported
+// by CollectStatus.
+type StatusEntry struct {
+	Version   int64  `json:"version"`
+	Source    string `json:"source"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// CollectStatus reports the applied/pending state of every migration in
+// dir, in version order. It underlies both Status's table output and the
+// -format=json CLI variant.
+func CollectStatus(db *sql.DB, dir string) ([]StatusEntry, error) {
+	return collectStatus(nil, db, dir, dialect, TableName())
+}
+
+// collectStatus is CollectStatus against an explicit fsys/dialect/tableName
+// instead of the package globals, so a Provider can use it without
+// depending on (or mutating) package-level state. fsys is nil for the
+// package-level API, which only ever reads migrations from dir on disk; a
+// Provider built with WithFS passes its own fsys so Status/StatusEntries
+// work the same way its other methods already do.
+func collectStatus(fsys fs.FS, db *sql.DB, dir string, d SqlDialect, tableName string) ([]StatusEntry, error) {
+	var migrations Migrations
+	var err error
+	if fsys != nil {
+		migrations, err = collectMigrationsFS(fsys, dir)
+	} else {
+		migrations, err = collectMigrations(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StatusEntry, 0, len(migrations))
+	for _, m := range migrations {
+		applied, ts, err := migrationAppliedAt(db, m.Version, d, tableName)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, StatusEntry{
+			Version:   m.Version,
+			Source:    m.Source,
+			Applied:   applied,
+			AppliedAt: ts,
+		})
+	}
+
+	return entries, nil
+}
+
+// Status prints the applied/pending state of every migration in dir.
+func Status(db *sql.DB, dir string) error {
+	entries, err := CollectStatus(db, dir)
+	if err != nil {
+		return err
+	}
+	return printStatusTable(entries)
+}
+
+// printStatusTable renders entries the same way Status does, factored out
+// so Provider.Status can reuse it against entries collected with the
+// Provider's own dialect/tableName.
+func printStatusTable(entries []StatusEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    Applied At\t\tMigration")
+	fmt.Fprintln(w, "    =======================================")
+
+	for _, e := range entries {
+		state := "Pending"
+		if e.Applied {
+			state = e.AppliedAt
+		}
+		fmt.Fprintf(w, "    %-24s\t%s\n", state, fmt.Sprintf("%d_%s", e.Version, e.Source))
+	}
+
+	return w.Flush()
+}
+
+func migrationAppliedAt(db *sql.DB, version int64, d SqlDialect, tableName string) (applied bool, timestamp string, err error) {
+	row := db.QueryRow(d.migrationSQL(tableName), version)
+
+	var ts string
+	var isApplied bool
+	if err := row.Scan(&ts, &isApplied); err != nil {
+		if err == sql.ErrNoRows {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	return isApplied, ts, nil
+}
+
+// Version prints the current version of the database.
+func Version(db *sql.DB, dir string) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("goose: version %v\n", current)
+	return nil
+}
@@ -0,0 +1,118 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+)
+
+// Up migrates db up to the most recent version available in dir.
+func Up(db *sql.DB, dir string) error {
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		if err == ErrNoNextVersion {
+			return nil
+		}
+		return err
+	}
+
+	return UpTo(db, dir, last.Version)
+}
+
+// UpByOne migrates db up by a single version.
+func UpByOne(db *sql.DB, dir string) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	next, err := migrations.Next(current)
+	if err != nil {
+		if err == ErrNoNextVersion {
+			fmt.Printf("goose: no migrations to run. current version: %d\n", current)
+			return nil
+		}
+		return err
+	}
+
+	return runMigration(db, next, true, dialect, TableName())
+}
+
+// UpTo migrates db up to a specific version.
+func UpTo(db *sql.DB, dir string, version int64) error {
+	current, err := GetDBVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collectMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrationsUpRange(migrations, current, version) {
+		if err := runMigration(db, m, true, dialect, TableName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runMigration runs a single migration, applies it in the given direction,
+// and records it in the goose_db_version table. d/tableName are explicit
+// rather than the package globals so a Provider can pass its own.
+func runMigration(db *sql.DB, m *Migration, direction bool, d SqlDialect, tableName string) error {
+	var err error
+	if m.Registered {
+		err = runGoMigration(db, m, direction)
+	} else {
+		err = runSQLMigration(db, m.Source, direction)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %v: %w", m.Source, err)
+	}
+
+	if err := recordVersion(db, nil, m, direction, d, tableName); err != nil {
+		return err
+	}
+
+	fmt.Printf("goose: migrating %v %v\n", direction2str(direction), m.Source)
+
+	return nil
+}
+
+// recordVersion inserts a row recording that m was applied (or rolled
+// back), including its checksum so Verify and -strict can detect drift
+// later. It lazily adds the checksum column to tables created before it
+// existed.
+func recordVersion(db *sql.DB, fsys fs.FS, m *Migration, direction bool, d SqlDialect, tableName string) error {
+	if err := ensureChecksumColumn(db, tableName); err != nil {
+		return err
+	}
+
+	sum, err := checksumMigration(fsys, m)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(d.insertVersionSQL(tableName), m.Version, direction, sum)
+	return err
+}
+
+func direction2str(direction bool) string {
+	if direction {
+		return "up"
+	}
+	return "down"
+}
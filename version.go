@@ -0,0 +1,99 @@
+package goose
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const defaultTableName = "goose_db_version"
+
+var tableName = defaultTableName
+
+// TableName returns goose migration table name.
+func TableName() string {
+	return tableName
+}
+
+// SetTableName sets goose migration table name.
+func SetTableName(n string) {
+	tableName = n
+}
+
+// GetDBVersion returns the current version: the most recently applied
+// version in the goose_db_version table that has not since been rolled
+// back. It creates the table if it does not yet exist.
+func GetDBVersion(db *sql.DB) (int64, error) {
+	return getDBVersion(db, dialect, TableName())
+}
+
+// getDBVersion is GetDBVersion against an explicit dialect/tableName
+// instead of the package globals, so a Provider can use it without
+// depending on (or mutating) package-level state.
+func getDBVersion(db *sql.DB, d SqlDialect, tableName string) (int64, error) {
+	rows, err := d.dbVersionQuery(db, tableName)
+	if err != nil {
+		return 0, createVersionTable(db, d, tableName)
+	}
+	defer rows.Close()
+
+	// The most recent record for each migration specifies whether it has
+	// been applied or rolled back. The first version we find that has been
+	// applied is the current version.
+	toSkip := make([]int64, 0)
+
+	for rows.Next() {
+		var row MigrationRecord
+		if err = rows.Scan(&row.VersionID, &row.IsApplied); err != nil {
+			return 0, fmt.Errorf("failed to scan migration record: %w", err)
+		}
+
+		skip := false
+		for _, v := range toSkip {
+			if v == row.VersionID {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		if row.IsApplied {
+			return row.VersionID, nil
+		}
+
+		toSkip = append(toSkip, row.VersionID)
+	}
+
+	return 0, nil
+}
+
+// createVersionTable creates the db version table and inserts the
+// initial 0 version row.
+func createVersionTable(db *sql.DB, d SqlDialect, tableName string) error {
+	txn, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := txn.Exec(d.createVersionTableSQL(tableName)); err != nil {
+		txn.Rollback()
+		return err
+	}
+
+	version := 0
+	applied := true
+	if _, err := txn.Exec(d.insertVersionSQL(tableName), version, applied, ""); err != nil {
+		txn.Rollback()
+		return err
+	}
+
+	return txn.Commit()
+}
+
+// EnsureDBVersion is an alias of GetDBVersion kept for readability at call
+// sites that only care about the table existing, not the version itself.
+func EnsureDBVersion(db *sql.DB) (int64, error) {
+	return GetDBVersion(db)
+}